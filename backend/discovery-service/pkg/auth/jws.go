@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/requie/ag/backend/discovery-service/pkg/store"
+)
+
+// JWSReplayWindow is how old a JWS's iat claim may be before it's
+// rejected as a possible replay.
+const JWSReplayWindow = 60 * time.Second
+
+// JWSAuthMiddleware authenticates agent-originated requests by a
+// JWS-signed Bearer token instead of a tenant API token: the header's
+// `kid` names the signer's JWK thumbprint, the signature is verified
+// against the stored keyset, and a stale `iat` is rejected as a replay.
+func JWSAuthMiddleware(agents store.AgentRepository) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			token := BearerToken(r)
+			if token == "" {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			claims, thumbprint, err := verifyJWS(r.Context(), agents, token)
+			if err != nil {
+				http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			iat, _ := claims["iat"].(float64)
+			if time.Since(time.Unix(int64(iat), 0)) > JWSReplayWindow {
+				http.Error(w, "Unauthorized: stale iat", http.StatusUnauthorized)
+				return
+			}
+
+			signer, err := agents.GetByThumbprint(r.Context(), thumbprint)
+			if err != nil {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			if signer.Status != "claimed" || signer.TenantID == nil {
+				http.Error(w, "Forbidden: agent not claimed", http.StatusForbidden)
+				return
+			}
+
+			ctx := WithAgentPrincipal(r.Context(), AgentPrincipal{AgentID: signer.ID, TenantID: *signer.TenantID})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// verifyJWS validates a compact JWS (header.payload.signature) against the
+// keyset of the agent named by its kid, and returns its payload claims and
+// signer kid (JWK thumbprint).
+func verifyJWS(ctx context.Context, agents store.AgentRepository, token string) (map[string]interface{}, string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, "", fmt.Errorf("malformed JWS")
+	}
+	headerB, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, "", fmt.Errorf("bad header encoding")
+	}
+	payloadB, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, "", fmt.Errorf("bad payload encoding")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, "", fmt.Errorf("bad signature encoding")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(headerB, &header); err != nil || header.Kid == "" {
+		return nil, "", fmt.Errorf("missing kid")
+	}
+
+	signer, err := agents.GetByThumbprint(ctx, header.Kid)
+	if err != nil {
+		return nil, "", fmt.Errorf("unknown kid")
+	}
+
+	signingInput := parts[0] + "." + parts[1]
+	if err := verifySignature(header.Alg, signer.KeySet, []byte(signingInput), sig); err != nil {
+		return nil, "", err
+	}
+
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payloadB, &claims); err != nil {
+		return nil, "", fmt.Errorf("bad payload")
+	}
+	return claims, header.Kid, nil
+}
+
+func verifySignature(alg string, jwk json.RawMessage, signingInput, sig []byte) error {
+	var key struct {
+		Kty string `json:"kty"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	}
+	if err := json.Unmarshal(jwk, &key); err != nil {
+		return fmt.Errorf("bad keyset")
+	}
+
+	hash := sha256.Sum256(signingInput)
+
+	switch alg {
+	case "ES256":
+		if key.Kty != "EC" || key.Crv != "P-256" {
+			return fmt.Errorf("alg/kty mismatch")
+		}
+		if len(sig) != 64 {
+			return fmt.Errorf("bad ES256 signature length")
+		}
+		x, err := decodeBigInt(key.X)
+		if err != nil {
+			return err
+		}
+		y, err := decodeBigInt(key.Y)
+		if err != nil {
+			return err
+		}
+		pub := ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(&pub, hash[:], r, s) {
+			return fmt.Errorf("signature verification failed")
+		}
+		return nil
+	case "RS256":
+		if key.Kty != "RSA" {
+			return fmt.Errorf("alg/kty mismatch")
+		}
+		n, err := decodeBigInt(key.N)
+		if err != nil {
+			return err
+		}
+		e, err := decodeBigInt(key.E)
+		if err != nil {
+			return err
+		}
+		pub := rsa.PublicKey{N: n, E: int(e.Int64())}
+		if err := rsa.VerifyPKCS1v15(&pub, crypto.SHA256, hash[:], sig); err != nil {
+			return fmt.Errorf("signature verification failed")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported alg %q", alg)
+	}
+}
+
+func decodeBigInt(b64 string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(b64)
+	if err != nil {
+		return nil, fmt.Errorf("bad base64url value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// JWKThumbprint computes the RFC 7638 SHA-256 thumbprint of a JWK's
+// required members in their canonical lexicographic order.
+func JWKThumbprint(jwk json.RawMessage) (string, error) {
+	var key struct {
+		Kty string `json:"kty"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		K   string `json:"k"`
+	}
+	if err := json.Unmarshal(jwk, &key); err != nil {
+		return "", err
+	}
+
+	var canonical string
+	switch key.Kty {
+	case "EC":
+		if key.Crv == "" || key.X == "" || key.Y == "" {
+			return "", fmt.Errorf("EC jwk missing crv/x/y")
+		}
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q,"y":%q}`, key.Crv, key.Kty, key.X, key.Y)
+	case "RSA":
+		if key.N == "" || key.E == "" {
+			return "", fmt.Errorf("RSA jwk missing n/e")
+		}
+		canonical = fmt.Sprintf(`{"e":%q,"kty":%q,"n":%q}`, key.E, key.Kty, key.N)
+	case "oct":
+		if key.K == "" {
+			return "", fmt.Errorf("oct jwk missing k")
+		}
+		canonical = fmt.Sprintf(`{"k":%q,"kty":%q}`, key.K, key.Kty)
+	default:
+		return "", fmt.Errorf("unsupported kty %q", key.Kty)
+	}
+
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
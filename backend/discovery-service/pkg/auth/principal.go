@@ -0,0 +1,49 @@
+// Package auth holds the two authentication schemes the discovery service
+// exposes: tenant-scoped bearer tokens for operator/API callers, and
+// per-agent JWS signatures for agent-facing endpoints.
+package auth
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/requie/ag/backend/discovery-service/pkg/model"
+)
+
+// TenantPrincipal is the tenant/role resolved from a bearer API token.
+type TenantPrincipal struct {
+	TenantID uuid.UUID
+	Role     model.Role
+}
+
+// AgentPrincipal is the agent/tenant resolved from a verified JWS.
+type AgentPrincipal struct {
+	AgentID  uuid.UUID
+	TenantID uuid.UUID
+}
+
+type ctxKey int
+
+const (
+	tenantPrincipalCtxKey ctxKey = iota
+	agentPrincipalCtxKey
+)
+
+func WithTenantPrincipal(ctx context.Context, p TenantPrincipal) context.Context {
+	return context.WithValue(ctx, tenantPrincipalCtxKey, p)
+}
+
+func TenantPrincipalFromContext(ctx context.Context) (TenantPrincipal, bool) {
+	p, ok := ctx.Value(tenantPrincipalCtxKey).(TenantPrincipal)
+	return p, ok
+}
+
+func WithAgentPrincipal(ctx context.Context, p AgentPrincipal) context.Context {
+	return context.WithValue(ctx, agentPrincipalCtxKey, p)
+}
+
+func AgentPrincipalFromContext(ctx context.Context) (AgentPrincipal, bool) {
+	p, ok := ctx.Value(agentPrincipalCtxKey).(AgentPrincipal)
+	return p, ok
+}
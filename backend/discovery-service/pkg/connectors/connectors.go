@@ -0,0 +1,173 @@
+// Package connectors implements the pluggable integration framework:
+// a typed Connector per supported system, each describing its own
+// config schema, validating submitted config, and exposing a health probe.
+package connectors
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// Schema is a minimal JSON-Schema-like description of a connector's
+// config shape, just enough for a UI to render a form and for Validate
+// to check required fields are present.
+type Schema struct {
+	Type       string                `json:"type"`
+	Properties map[string]SchemaProp `json:"properties"`
+	Required   []string              `json:"required"`
+}
+
+type SchemaProp struct {
+	Type string `json:"type"`
+}
+
+// Connector is the interface every supported integration type implements.
+type Connector interface {
+	Type() string
+	ConfigSchema() Schema
+	Validate(cfg json.RawMessage) error
+	Health(ctx context.Context, cfg json.RawMessage) error
+}
+
+var registry = map[string]Connector{}
+
+func register(c Connector) {
+	registry[c.Type()] = c
+}
+
+func init() {
+	register(postgresConnector{})
+	register(s3Connector{})
+	register(httpWebhookConnector{})
+	register(slackConnector{})
+}
+
+// Lookup returns the registered connector for a type, if any.
+func Lookup(connectorType string) (Connector, bool) {
+	c, ok := registry[connectorType]
+	return c, ok
+}
+
+// All returns every registered connector, for listing.
+func All() map[string]Connector {
+	return registry
+}
+
+// requiredStringFields validates that cfg decodes to an object with each
+// of the given keys present as a non-empty string. It's shared by the
+// built-in connectors below, which all take a flat set of string options.
+func requiredStringFields(cfg json.RawMessage, fields ...string) error {
+	var values map[string]interface{}
+	if err := json.Unmarshal(cfg, &values); err != nil {
+		return fmt.Errorf("config must be a JSON object: %w", err)
+	}
+	for _, f := range fields {
+		v, ok := values[f].(string)
+		if !ok || v == "" {
+			return fmt.Errorf("config.%s is required", f)
+		}
+	}
+	return nil
+}
+
+func stringSchema(fields ...string) Schema {
+	props := make(map[string]SchemaProp, len(fields))
+	for _, f := range fields {
+		props[f] = SchemaProp{Type: "string"}
+	}
+	return Schema{Type: "object", Properties: props, Required: fields}
+}
+
+type postgresConnector struct{}
+
+func (postgresConnector) Type() string         { return "postgres" }
+func (postgresConnector) ConfigSchema() Schema { return stringSchema("dsn") }
+func (postgresConnector) Validate(cfg json.RawMessage) error {
+	return requiredStringFields(cfg, "dsn")
+}
+func (postgresConnector) Health(ctx context.Context, cfg json.RawMessage) error {
+	var conf struct {
+		DSN string `json:"dsn"`
+	}
+	if err := json.Unmarshal(cfg, &conf); err != nil {
+		return err
+	}
+	conn, err := sql.Open("postgres", conf.DSN)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.PingContext(ctx)
+}
+
+type s3Connector struct{}
+
+func (s3Connector) Type() string         { return "s3" }
+func (s3Connector) ConfigSchema() Schema { return stringSchema("bucket", "region") }
+func (s3Connector) Validate(cfg json.RawMessage) error {
+	return requiredStringFields(cfg, "bucket", "region")
+}
+func (s3Connector) Health(ctx context.Context, cfg json.RawMessage) error {
+	// MVP: no AWS SDK dependency yet, so we only re-validate config shape.
+	return s3Connector{}.Validate(cfg)
+}
+
+type httpWebhookConnector struct{}
+
+func (httpWebhookConnector) Type() string         { return "http_webhook" }
+func (httpWebhookConnector) ConfigSchema() Schema { return stringSchema("url") }
+func (httpWebhookConnector) Validate(cfg json.RawMessage) error {
+	return requiredStringFields(cfg, "url")
+}
+func (httpWebhookConnector) Health(ctx context.Context, cfg json.RawMessage) error {
+	var conf struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(cfg, &conf); err != nil {
+		return err
+	}
+	return probeURL(ctx, conf.URL)
+}
+
+type slackConnector struct{}
+
+func (slackConnector) Type() string         { return "slack" }
+func (slackConnector) ConfigSchema() Schema { return stringSchema("webhook_url") }
+func (slackConnector) Validate(cfg json.RawMessage) error {
+	return requiredStringFields(cfg, "webhook_url")
+}
+func (slackConnector) Health(ctx context.Context, cfg json.RawMessage) error {
+	var conf struct {
+		WebhookURL string `json:"webhook_url"`
+	}
+	if err := json.Unmarshal(cfg, &conf); err != nil {
+		return err
+	}
+	return probeURL(ctx, conf.WebhookURL)
+}
+
+// probeURL confirms a webhook endpoint is reachable; Slack and generic
+// webhooks both reject GETs with a 4xx, so anything short of a network
+// error or 5xx counts as healthy.
+func probeURL(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	client := http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("endpoint returned %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/requie/ag/backend/discovery-service/pkg/auth"
+	"github.com/requie/ag/backend/discovery-service/pkg/store"
+)
+
+func (a *API) GetAgents(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.TenantPrincipalFromContext(r.Context())
+
+	agents, err := a.Agents.List(r.Context(), principal.TenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, agents)
+}
+
+func (a *API) ClaimAgent(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.TenantPrincipalFromContext(r.Context())
+
+	vars := mux.Vars(r)
+	agentID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid agent ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		ClaimCode  string `json:"claim_code"`
+		OwnerEmail string `json:"owner_email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.ClaimCode == "" {
+		http.Error(w, "claim_code is required", http.StatusBadRequest)
+		return
+	}
+
+	a.withIdempotencyKey(w, r, func() (int, interface{}) {
+		agent, err := a.Agents.Claim(r.Context(), agentID, req.ClaimCode, principal.TenantID, req.OwnerEmail)
+		if err != nil {
+			if err == store.ErrNotFound {
+				http.Error(w, "Agent not found, already claimed, or claim_code mismatch", http.StatusNotFound)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return 0, nil
+		}
+		return http.StatusOK, agent
+	})
+}
+
+// AttachAgent moves an unclaimed agent into a tenant, replacing any tenant
+// it is already attached to.
+func (a *API) AttachAgent(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	agentID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid agent ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		TenantID string `json:"tenant_id"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	tenantID, err := uuid.Parse(req.TenantID)
+	if err != nil {
+		http.Error(w, "tenant_id is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.Agents.Attach(r.Context(), agentID, tenantID); err != nil {
+		if err == store.ErrNotFound {
+			http.Error(w, "Agent not found or not unclaimed", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DetachAgent clears an unclaimed agent's tenant so it can be re-attached.
+func (a *API) DetachAgent(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	agentID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid agent ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.Agents.Detach(r.Context(), agentID); err != nil {
+		if err == store.ErrNotFound {
+			http.Error(w, "Agent not found or not unclaimed", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,37 @@
+// Package httpapi wires the HTTP handlers for the discovery service to
+// the store and auth packages. It holds no business logic of its own
+// beyond request decoding/encoding and status-code mapping.
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/requie/ag/backend/discovery-service/pkg/store"
+)
+
+// API holds the dependencies shared by every HTTP handler.
+type API struct {
+	Agents      store.AgentRepository
+	Tenants     *store.TenantStore
+	Jobs        *store.JobStore
+	Health      *store.ConnectorHealthStore
+	Idempotency *store.IdempotencyStore
+	DBConnStr   string
+}
+
+func NewAPI(agents store.AgentRepository, tenants *store.TenantStore, jobs *store.JobStore, health *store.ConnectorHealthStore, idempotency *store.IdempotencyStore, dbConnStr string) *API {
+	return &API{
+		Agents:      agents,
+		Tenants:     tenants,
+		Jobs:        jobs,
+		Health:      health,
+		Idempotency: idempotency,
+		DBConnStr:   dbConnStr,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,70 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/requie/ag/backend/discovery-service/pkg/auth"
+	"github.com/requie/ag/backend/discovery-service/pkg/connectors"
+)
+
+// ListConnectors returns every registered connector's type and config
+// schema so a UI can render a form for each.
+func (a *API) ListConnectors(w http.ResponseWriter, r *http.Request) {
+	type connectorInfo struct {
+		Type   string            `json:"type"`
+		Schema connectors.Schema `json:"schema"`
+	}
+	all := connectors.All()
+	infos := make([]connectorInfo, 0, len(all))
+	for t, c := range all {
+		infos = append(infos, connectorInfo{Type: t, Schema: c.ConfigSchema()})
+	}
+
+	writeJSON(w, infos)
+}
+
+// CheckAgentHealth dispatches to the agent's connector Health probe and
+// records the outcome.
+func (a *API) CheckAgentHealth(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.TenantPrincipalFromContext(r.Context())
+
+	vars := mux.Vars(r)
+	agentID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid agent ID", http.StatusBadRequest)
+		return
+	}
+
+	connectorType, config, err := a.Agents.GetConnectorConfig(r.Context(), agentID, principal.TenantID)
+	if err != nil {
+		http.Error(w, "Agent not found", http.StatusNotFound)
+		return
+	}
+
+	connector, ok := connectors.Lookup(connectorType)
+	if !ok {
+		http.Error(w, "unknown connector_type", http.StatusUnprocessableEntity)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	healthErr := connector.Health(ctx, config)
+	ok2, message := true, ""
+	if healthErr != nil {
+		ok2, message = false, healthErr.Error()
+	}
+
+	if _, err := a.Health.Record(r.Context(), agentID, ok2, message); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{"ok": ok2, "message": message})
+}
@@ -0,0 +1,60 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/requie/ag/backend/discovery-service/pkg/auth"
+)
+
+// withIdempotencyKey makes fn's response replayable: if the request carries
+// an Idempotency-Key header that's already been seen for the caller's
+// tenant and this route, the cached response is written back and fn is not
+// called. Otherwise fn runs and its response is cached under that key
+// before being written. The key is scoped to (tenant, method, path) since
+// two different tenants or endpoints can legitimately reuse the same
+// Idempotency-Key header value.
+//
+// fn returns the status code and response body to send; a zero status
+// means fn already wrote its own response (e.g. via http.Error) and
+// nothing should be cached.
+func (a *API) withIdempotencyKey(w http.ResponseWriter, r *http.Request, fn func() (status int, body interface{})) {
+	key := r.Header.Get("Idempotency-Key")
+	if key == "" {
+		status, body := fn()
+		if status != 0 {
+			writeJSONStatus(w, status, body)
+		}
+		return
+	}
+
+	principal, _ := auth.TenantPrincipalFromContext(r.Context())
+	method, path := r.Method, r.URL.Path
+
+	cached, err := a.Idempotency.Get(r.Context(), principal.TenantID, method, path, key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if cached != nil {
+		writeJSONStatus(w, cached.Status, cached.Body)
+		return
+	}
+
+	status, body := fn()
+	if status == 0 {
+		return
+	}
+
+	respBody, err := json.Marshal(body)
+	if err == nil {
+		_ = a.Idempotency.Put(r.Context(), principal.TenantID, method, path, key, status, respBody)
+	}
+	writeJSONStatus(w, status, body)
+}
+
+func writeJSONStatus(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,139 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/requie/ag/backend/discovery-service/pkg/auth"
+	"github.com/requie/ag/backend/discovery-service/pkg/connectors"
+	"github.com/requie/ag/backend/discovery-service/pkg/model"
+)
+
+// EnrollAgent lets an agent register itself with a JWK public key instead
+// of a tenant minting it on the agent's behalf. connector_type must name a
+// registered connector, and config is checked by that connector's
+// Validate. The agent is created unclaimed; a
+// one-time claim code is returned for a tenant operator to bind it with
+// ClaimAgent.
+func (a *API) EnrollAgent(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name          string          `json:"name"`
+		ConnectorType string          `json:"connector_type"`
+		Config        json.RawMessage `json:"config"`
+		JWK           json.RawMessage `json:"jwk"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" || req.ConnectorType == "" || len(req.JWK) == 0 {
+		http.Error(w, "name, connector_type and jwk are required", http.StatusBadRequest)
+		return
+	}
+
+	connector, ok := connectors.Lookup(req.ConnectorType)
+	if !ok {
+		http.Error(w, "unknown connector_type", http.StatusBadRequest)
+		return
+	}
+	if len(req.Config) == 0 {
+		req.Config = json.RawMessage("{}")
+	}
+	if err := connector.Validate(req.Config); err != nil {
+		http.Error(w, "invalid config: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	thumbprint, err := auth.JWKThumbprint(req.JWK)
+	if err != nil {
+		http.Error(w, "invalid jwk: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	claimCode, err := auth.GenerateToken()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	agent := model.Agent{
+		ID:            uuid.New(),
+		Name:          req.Name,
+		ConnectorType: req.ConnectorType,
+		Status:        "unclaimed",
+		Thumbprint:    &thumbprint,
+		KeySet:        req.JWK,
+		Config:        req.Config,
+		ConfigVersion: 1,
+		CreatedAt:     time.Now(),
+	}
+
+	if err := a.Agents.Enroll(r.Context(), &agent, claimCode); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, map[string]string{
+		"agent_id":   agent.ID.String(),
+		"claim_code": claimCode,
+	})
+}
+
+// RotateAgentKey replaces an enrolled agent's JWK, authenticated by a JWS
+// signed with the *current* key (JWSAuthMiddleware has already verified
+// that before this handler runs).
+func (a *API) RotateAgentKey(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.AgentPrincipalFromContext(r.Context())
+
+	vars := mux.Vars(r)
+	agentID, err := uuid.Parse(vars["id"])
+	if err != nil || agentID != principal.AgentID {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		JWK json.RawMessage `json:"jwk"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.JWK) == 0 {
+		http.Error(w, "jwk is required", http.StatusBadRequest)
+		return
+	}
+
+	newThumbprint, err := auth.JWKThumbprint(req.JWK)
+	if err != nil {
+		http.Error(w, "invalid jwk: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.Agents.RotateKey(r.Context(), agentID, newThumbprint, req.JWK); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Heartbeat lets a claimed agent refresh its last_active timestamp
+// without necessarily pulling a job.
+func (a *API) Heartbeat(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.AgentPrincipalFromContext(r.Context())
+
+	vars := mux.Vars(r)
+	agentID, err := uuid.Parse(vars["id"])
+	if err != nil || agentID != principal.AgentID {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	if err := a.Agents.UpdateLastActive(r.Context(), agentID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
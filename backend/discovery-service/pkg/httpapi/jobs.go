@@ -0,0 +1,351 @@
+package httpapi
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/requie/ag/backend/discovery-service/pkg/auth"
+	"github.com/requie/ag/backend/discovery-service/pkg/store"
+)
+
+const (
+	jobPullTimeout = 30 * time.Second
+	streamTokenTTL = 60 * time.Second
+)
+
+// CreateJob enqueues a job for an agent and wakes up any pull currently
+// long-polling for it.
+func (a *API) CreateJob(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.TenantPrincipalFromContext(r.Context())
+
+	vars := mux.Vars(r)
+	agentID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid agent ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Payload json.RawMessage `json:"payload"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	job, err := a.Jobs.CreateJob(r.Context(), agentID, principal.TenantID, req.Payload)
+	if err != nil {
+		if err == store.ErrNotFound {
+			http.Error(w, "Agent not found", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, job)
+}
+
+// PullJob long-polls for up to jobPullTimeout for the next queued job
+// belonging to the agent, waking up on Postgres LISTEN/NOTIFY instead of
+// busy-polling. It also refreshes the agent's last_active timestamp.
+func (a *API) PullJob(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.AgentPrincipalFromContext(r.Context())
+
+	vars := mux.Vars(r)
+	agentID, err := uuid.Parse(vars["id"])
+	if err != nil || agentID != principal.AgentID {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	if err := a.Agents.UpdateLastActive(r.Context(), agentID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), jobPullTimeout)
+	defer cancel()
+
+	job, err := a.Jobs.ClaimNextJob(ctx, agentID, principal.TenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if job != nil {
+		writeJSON(w, job)
+		return
+	}
+
+	woken := make(chan struct{}, 1)
+	listener := a.Jobs.ListenForJob(ctx, a.DBConnStr, agentID, func() {
+		select {
+		case woken <- struct{}{}:
+		default:
+		}
+	})
+	defer listener.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.WriteHeader(http.StatusNoContent)
+			return
+		case <-woken:
+		case <-time.After(5 * time.Second):
+			// Guard against a missed NOTIFY by re-checking periodically.
+		}
+
+		job, err := a.Jobs.ClaimNextJob(ctx, agentID, principal.TenantID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if job != nil {
+			writeJSON(w, job)
+			return
+		}
+	}
+}
+
+func (a *API) UpdateJobStatus(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.AgentPrincipalFromContext(r.Context())
+
+	vars := mux.Vars(r)
+	jobID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+	if !a.Jobs.JobOwnedByAgent(r.Context(), jobID, principal.AgentID) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		Status string `json:"status"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	if req.Status == "" {
+		http.Error(w, "status is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.Jobs.UpdateStatus(r.Context(), jobID, req.Status); err != nil {
+		if err == store.ErrNotFound {
+			http.Error(w, "Job not found", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AppendJobLogs accepts a chunked ndjson body from the agent, one log
+// line per object, and assigns each a monotonically increasing seq.
+func (a *API) AppendJobLogs(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.AgentPrincipalFromContext(r.Context())
+
+	vars := mux.Vars(r)
+	jobID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+	if !a.Jobs.JobOwnedByAgent(r.Context(), jobID, principal.AgentID) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	nextSeq, err := a.Jobs.NextLogSeq(r.Context(), jobID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var entry struct {
+			Stream string `json:"stream"`
+			Data   string `json:"data"`
+		}
+		if err := json.Unmarshal(line, &entry); err != nil {
+			http.Error(w, "invalid ndjson line: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := a.Jobs.AppendLog(r.Context(), jobID, nextSeq, entry.Stream, entry.Data); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		nextSeq++
+	}
+	if err := scanner.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// IssueStreamToken mints a short-lived HMAC token for the log-stream
+// endpoint, since a browser EventSource can't set an Authorization header.
+func (a *API) IssueStreamToken(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.TenantPrincipalFromContext(r.Context())
+
+	vars := mux.Vars(r)
+	jobID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	tenantID, err := a.Jobs.TenantOf(r.Context(), jobID)
+	if err != nil {
+		if err == store.ErrNotFound {
+			http.Error(w, "Job not found", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if tenantID != principal.TenantID {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	token, err := signStreamToken(jobID, time.Now().Add(streamTokenTTL))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, map[string]string{"token": token})
+}
+
+func signStreamToken(jobID uuid.UUID, expiresAt time.Time) (string, error) {
+	secret := streamSigningKey()
+	payload := fmt.Sprintf("%s.%d", jobID.String(), expiresAt.Unix())
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	sig := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + sig, nil
+}
+
+func verifyStreamToken(jobID uuid.UUID, token string) bool {
+	parts := splitToken(token)
+	if len(parts) != 2 {
+		return false
+	}
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return false
+	}
+	payload := string(payloadBytes)
+
+	secret := streamSigningKey()
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payloadBytes)
+	expectedSig := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expectedSig), []byte(parts[1])) {
+		return false
+	}
+
+	prefix := jobID.String() + "."
+	if len(payload) <= len(prefix) || payload[:len(prefix)] != prefix {
+		return false
+	}
+	expUnix, err := strconv.ParseInt(payload[len(prefix):], 10, 64)
+	if err != nil {
+		return false
+	}
+	return time.Now().Unix() <= expUnix
+}
+
+func splitToken(token string) []string {
+	for i := len(token) - 1; i >= 0; i-- {
+		if token[i] == '.' {
+			return []string{token[:i], token[i+1:]}
+		}
+	}
+	return []string{token}
+}
+
+func streamSigningKey() []byte {
+	key := os.Getenv("STREAM_SIGNING_KEY")
+	if key == "" {
+		key = os.Getenv("API_KEY")
+	}
+	return []byte(key)
+}
+
+// StreamJobLogs proxies job logs to a browser over Server-Sent Events,
+// authenticated by the short-lived token minted by IssueStreamToken
+// rather than a header, since EventSource cannot set one.
+func (a *API) StreamJobLogs(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	jobID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	if token == "" || !verifyStreamToken(jobID, token) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	lastSeq := -1
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			logs, err := a.Jobs.LogsSince(r.Context(), jobID, lastSeq)
+			if err != nil {
+				return
+			}
+			for _, l := range logs {
+				payload, _ := json.Marshal(l)
+				fmt.Fprintf(w, "data: %s\n\n", payload)
+				lastSeq = l.Seq
+			}
+			flusher.Flush()
+
+			status, err := a.Jobs.Status(r.Context(), jobID)
+			if err == nil && (status == "succeeded" || status == "failed") {
+				fmt.Fprintf(w, "event: done\ndata: {}\n\n")
+				flusher.Flush()
+				return
+			}
+		}
+	}
+}
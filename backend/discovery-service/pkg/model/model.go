@@ -0,0 +1,93 @@
+// Package model holds the domain types shared across the discovery
+// service's store, auth and HTTP layers.
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role is the permission level bound to a minted API token.
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleWriter Role = "writer"
+	RoleReader Role = "reader"
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleWriter, RoleReader:
+		return true
+	}
+	return false
+}
+
+// AtLeast reports whether r satisfies a minimum required role, where
+// admin > writer > reader.
+func (r Role) AtLeast(min Role) bool {
+	rank := map[Role]int{RoleReader: 0, RoleWriter: 1, RoleAdmin: 2}
+	return rank[r] >= rank[min]
+}
+
+type Tenant struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type APIToken struct {
+	ID        uuid.UUID  `json:"id"`
+	TenantID  uuid.UUID  `json:"tenant_id"`
+	Token     string     `json:"token,omitempty"`
+	Role      Role       `json:"role"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+type Agent struct {
+	ID            uuid.UUID       `json:"id"`
+	TenantID      *uuid.UUID      `json:"tenant_id"`
+	Name          string          `json:"name"`
+	OwnerEmail    string          `json:"owner_email"`
+	ConnectorType string          `json:"connector_type"`
+	Status        string          `json:"status"`
+	Thumbprint    *string         `json:"thumbprint,omitempty"`
+	KeySet        json.RawMessage `json:"keyset,omitempty"`
+	Config        json.RawMessage `json:"config,omitempty"`
+	ConfigVersion int             `json:"config_version"`
+	CreatedAt     time.Time       `json:"created_at"`
+	LastActive    *time.Time      `json:"last_active"`
+}
+
+// Job is a unit of work dispatched to an agent and pulled over long-poll.
+type Job struct {
+	ID         uuid.UUID       `json:"id"`
+	AgentID    uuid.UUID       `json:"agent_id"`
+	TenantID   uuid.UUID       `json:"tenant_id"`
+	Payload    json.RawMessage `json:"payload"`
+	Status     string          `json:"status"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  *time.Time      `json:"started_at"`
+	FinishedAt *time.Time      `json:"finished_at"`
+}
+
+// JobLog is a single chunk of a job's stdout/stderr, ordered by Seq.
+type JobLog struct {
+	JobID  uuid.UUID `json:"job_id"`
+	Seq    int       `json:"seq"`
+	Stream string    `json:"stream"`
+	Data   string    `json:"data"`
+	Ts     time.Time `json:"ts"`
+}
+
+// AgentHealth is the recorded outcome of a connector Health probe.
+type AgentHealth struct {
+	AgentID   uuid.UUID `json:"agent_id"`
+	OK        bool      `json:"ok"`
+	Message   string    `json:"message"`
+	CheckedAt time.Time `json:"checked_at"`
+}
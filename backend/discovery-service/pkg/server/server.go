@@ -0,0 +1,40 @@
+// Package server wires the discovery service's HTTP routes to their
+// handlers and auth middleware.
+package server
+
+import (
+	"github.com/gorilla/mux"
+
+	"github.com/requie/ag/backend/discovery-service/pkg/auth"
+	"github.com/requie/ag/backend/discovery-service/pkg/httpapi"
+	"github.com/requie/ag/backend/discovery-service/pkg/model"
+	"github.com/requie/ag/backend/discovery-service/pkg/store"
+)
+
+// NewRouter builds the discovery service's full route table.
+func NewRouter(api *httpapi.API, tenants *store.TenantStore, agents store.AgentRepository) *mux.Router {
+	r := mux.NewRouter()
+
+	r.HandleFunc("/v1/agents", auth.TokenMiddleware(tenants, model.RoleReader)(api.GetAgents)).Methods("GET")
+	r.HandleFunc("/v1/agents/enroll", api.EnrollAgent).Methods("POST")
+	r.HandleFunc("/v1/agents/{id}/claim", auth.TokenMiddleware(tenants, model.RoleWriter)(api.ClaimAgent)).Methods("POST")
+	r.HandleFunc("/v1/agents/{id}/keys", auth.JWSAuthMiddleware(agents)(api.RotateAgentKey)).Methods("POST")
+	r.HandleFunc("/v1/agents/{id}/heartbeat", auth.JWSAuthMiddleware(agents)(api.Heartbeat)).Methods("POST")
+	r.HandleFunc("/v1/agents/{id}/attach", auth.AdminKeyMiddleware(api.AttachAgent)).Methods("POST")
+	r.HandleFunc("/v1/agents/{id}/detach", auth.AdminKeyMiddleware(api.DetachAgent)).Methods("POST")
+	r.HandleFunc("/v1/connectors", auth.TokenMiddleware(tenants, model.RoleReader)(api.ListConnectors)).Methods("GET")
+	r.HandleFunc("/v1/agents/{id}/health", auth.TokenMiddleware(tenants, model.RoleReader)(api.CheckAgentHealth)).Methods("POST")
+	r.HandleFunc("/v1/agents/{id}/jobs", auth.TokenMiddleware(tenants, model.RoleWriter)(api.CreateJob)).Methods("POST")
+	r.HandleFunc("/v1/agents/{id}/pull", auth.JWSAuthMiddleware(agents)(api.PullJob)).Methods("POST")
+	r.HandleFunc("/v1/jobs/{id}/status", auth.JWSAuthMiddleware(agents)(api.UpdateJobStatus)).Methods("POST")
+	r.HandleFunc("/v1/jobs/{id}/logs", auth.JWSAuthMiddleware(agents)(api.AppendJobLogs)).Methods("POST")
+	r.HandleFunc("/v1/jobs/{id}/stream-token", auth.TokenMiddleware(tenants, model.RoleReader)(api.IssueStreamToken)).Methods("POST")
+	r.HandleFunc("/v1/jobs/{id}/logs/stream", api.StreamJobLogs).Methods("GET")
+
+	r.HandleFunc("/v1/tenants", auth.AdminKeyMiddleware(api.CreateTenant)).Methods("POST")
+	r.HandleFunc("/v1/tenants/{id}/tokens", auth.TenantAdminMiddleware(tenants)(api.MintToken)).Methods("POST")
+	r.HandleFunc("/v1/tenants/{id}/tokens", auth.TenantAdminMiddleware(tenants)(api.ListTokens)).Methods("GET")
+	r.HandleFunc("/v1/tenants/{id}/tokens/{token_id}", auth.TenantAdminMiddleware(tenants)(api.RevokeToken)).Methods("DELETE")
+
+	return r
+}
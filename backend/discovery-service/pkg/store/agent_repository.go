@@ -0,0 +1,25 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+
+	"github.com/requie/ag/backend/discovery-service/pkg/model"
+)
+
+// AgentRepository abstracts agent persistence behind an interface so the
+// HTTP layer doesn't depend on the Postgres driver directly.
+type AgentRepository interface {
+	Enroll(ctx context.Context, agent *model.Agent, claimCode string) error
+	Get(ctx context.Context, id uuid.UUID) (*model.Agent, error)
+	GetByThumbprint(ctx context.Context, thumbprint string) (*model.Agent, error)
+	List(ctx context.Context, tenantID uuid.UUID) ([]model.Agent, error)
+	Claim(ctx context.Context, id uuid.UUID, claimCode string, tenantID uuid.UUID, ownerEmail string) (*model.Agent, error)
+	Attach(ctx context.Context, id uuid.UUID, tenantID uuid.UUID) error
+	Detach(ctx context.Context, id uuid.UUID) error
+	RotateKey(ctx context.Context, id uuid.UUID, thumbprint string, keyset json.RawMessage) error
+	UpdateLastActive(ctx context.Context, id uuid.UUID) error
+	GetConnectorConfig(ctx context.Context, id uuid.UUID, tenantID uuid.UUID) (connectorType string, config json.RawMessage, err error)
+}
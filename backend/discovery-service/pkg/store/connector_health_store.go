@@ -0,0 +1,31 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/requie/ag/backend/discovery-service/pkg/model"
+)
+
+// ConnectorHealthStore persists the outcome of connector Health probes.
+type ConnectorHealthStore struct {
+	db *sqlx.DB
+}
+
+func NewConnectorHealthStore(db *sqlx.DB) *ConnectorHealthStore {
+	return &ConnectorHealthStore{db: db}
+}
+
+func (s *ConnectorHealthStore) Record(ctx context.Context, agentID uuid.UUID, ok bool, message string) (*model.AgentHealth, error) {
+	health := model.AgentHealth{AgentID: agentID, OK: ok, Message: message, CheckedAt: time.Now()}
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO agent_health (agent_id, ok, message, checked_at) VALUES ($1, $2, $3, $4)`,
+		health.AgentID, health.OK, health.Message, health.CheckedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
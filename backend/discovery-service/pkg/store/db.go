@@ -0,0 +1,16 @@
+package store
+
+import (
+	"github.com/jmoiron/sqlx"
+
+	_ "github.com/lib/pq"
+)
+
+// Open connects to Postgres via sqlx and verifies the connection.
+func Open(connStr string) (*sqlx.DB, error) {
+	db, err := sqlx.Connect("postgres", connStr)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,6 @@
+package store
+
+import "errors"
+
+// ErrNotFound is returned by repository lookups that found no matching row.
+var ErrNotFound = errors.New("not found")
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// idempotencyTTL bounds how long a cached response is replayed for a
+// retried request before the key is treated as fresh again.
+const idempotencyTTL = 24 * time.Hour
+
+// IdempotentResponse is a cached HTTP response keyed by an Idempotency-Key
+// header value.
+type IdempotentResponse struct {
+	Status int
+	Body   json.RawMessage
+}
+
+// IdempotencyStore persists Idempotency-Key request/response pairs so
+// retried POSTs return the original response instead of creating duplicates.
+type IdempotencyStore struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencyStore(db *sqlx.DB) *IdempotencyStore {
+	return &IdempotencyStore{db: db}
+}
+
+// Get returns the cached response for (tenantID, method, path, key), or
+// (nil, nil) if no live entry exists (not found, or found but past
+// idempotencyTTL). The key alone is not a safe lookup: two tenants (or two
+// routes) can legitimately submit the same Idempotency-Key header value,
+// and without this scoping the second caller would be handed back the
+// first caller's cached response.
+func (s *IdempotencyStore) Get(ctx context.Context, tenantID uuid.UUID, method, path, key string) (*IdempotentResponse, error) {
+	var status int
+	var body []byte
+	var createdAt time.Time
+	err := s.db.QueryRowContext(ctx,
+		`SELECT response_status, response_body, created_at FROM idempotency_keys WHERE tenant_id = $1 AND method = $2 AND path = $3 AND key = $4`,
+		tenantID, method, path, key).
+		Scan(&status, &body, &createdAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if time.Since(createdAt) > idempotencyTTL {
+		return nil, nil
+	}
+	return &IdempotentResponse{Status: status, Body: body}, nil
+}
+
+// Put records the response for (tenantID, method, path, key), so subsequent
+// retries with the same key replay it instead of re-executing the request.
+// It overwrites an existing row rather than no-op'ing on conflict: once Get
+// treats a row older than idempotencyTTL as expired, the key must be
+// refreshable, or it stays permanently expired and every retry re-executes
+// the request.
+func (s *IdempotencyStore) Put(ctx context.Context, tenantID uuid.UUID, method, path, key string, status int, body json.RawMessage) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO idempotency_keys (tenant_id, method, path, key, response_status, response_body, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 ON CONFLICT (tenant_id, method, path, key) DO UPDATE SET response_status = EXCLUDED.response_status, response_body = EXCLUDED.response_body, created_at = EXCLUDED.created_at`,
+		tenantID, method, path, key, status, body, time.Now())
+	return err
+}
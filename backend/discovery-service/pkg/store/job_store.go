@@ -0,0 +1,199 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/requie/ag/backend/discovery-service/pkg/model"
+)
+
+// jobAvailableChan is the Postgres NOTIFY channel used to wake up agents
+// long-polling in httpapi's PullJob.
+const jobAvailableChan = "job_available"
+
+// JobStore persists jobs and their streamed logs.
+type JobStore struct {
+	db *sqlx.DB
+}
+
+func NewJobStore(db *sqlx.DB) *JobStore {
+	return &JobStore{db: db}
+}
+
+// CreateJob enqueues a job for an agent scoped to tenantID and wakes up
+// any pull currently long-polling for it. ErrNotFound is returned if the
+// agent doesn't belong to the tenant.
+func (s *JobStore) CreateJob(ctx context.Context, agentID, tenantID uuid.UUID, payload []byte) (*model.Job, error) {
+	job := model.Job{
+		ID:        uuid.New(),
+		AgentID:   agentID,
+		TenantID:  tenantID,
+		Payload:   payload,
+		Status:    "queued",
+		CreatedAt: time.Now(),
+	}
+
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO jobs (id, agent_id, tenant_id, payload, status, created_at)
+		 SELECT $1, $2, $3, $4, $5, $6 WHERE EXISTS (SELECT 1 FROM agents WHERE id = $2 AND tenant_id = $3)`,
+		job.ID, job.AgentID, job.TenantID, job.Payload, job.Status, job.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrNotFound
+	}
+
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("NOTIFY %s, '%s'", jobAvailableChan, agentID.String())); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ClaimNextJob atomically picks the oldest queued job for the agent and
+// marks it running, so concurrent pulls never hand out the same job
+// twice. It returns (nil, nil) if no job is queued.
+func (s *JobStore) ClaimNextJob(ctx context.Context, agentID, tenantID uuid.UUID) (*model.Job, error) {
+	row := s.db.QueryRowContext(ctx, `
+		UPDATE jobs SET status = 'running', started_at = $1
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE agent_id = $2 AND tenant_id = $3 AND status = 'queued'
+			ORDER BY created_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING id, agent_id, tenant_id, payload, status, created_at, started_at, finished_at`,
+		time.Now(), agentID, tenantID)
+
+	var job model.Job
+	var startedAt, finishedAt sql.NullTime
+	err := row.Scan(&job.ID, &job.AgentID, &job.TenantID, &job.Payload, &job.Status, &job.CreatedAt, &startedAt, &finishedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	return &job, nil
+}
+
+func (s *JobStore) JobOwnedByAgent(ctx context.Context, jobID, agentID uuid.UUID) bool {
+	var owner uuid.UUID
+	if err := s.db.QueryRowContext(ctx, `SELECT agent_id FROM jobs WHERE id = $1`, jobID).Scan(&owner); err != nil {
+		return false
+	}
+	return owner == agentID
+}
+
+// TenantOf returns the tenant a job belongs to, so handlers minting
+// tenant-scoped credentials for a job (e.g. a log-stream token) can check
+// the caller is actually allowed to see it.
+func (s *JobStore) TenantOf(ctx context.Context, jobID uuid.UUID) (uuid.UUID, error) {
+	var tenantID uuid.UUID
+	err := s.db.QueryRowContext(ctx, `SELECT tenant_id FROM jobs WHERE id = $1`, jobID).Scan(&tenantID)
+	if err == sql.ErrNoRows {
+		return uuid.Nil, ErrNotFound
+	}
+	return tenantID, err
+}
+
+// UpdateStatus transitions a job's status, stamping finished_at when the
+// new status is terminal.
+func (s *JobStore) UpdateStatus(ctx context.Context, jobID uuid.UUID, status string) error {
+	var finishedAt interface{}
+	if status == "succeeded" || status == "failed" {
+		finishedAt = time.Now()
+	}
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE jobs SET status = $1, finished_at = COALESCE($2, finished_at) WHERE id = $3`,
+		status, finishedAt, jobID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *JobStore) Status(ctx context.Context, jobID uuid.UUID) (string, error) {
+	var status string
+	err := s.db.QueryRowContext(ctx, `SELECT status FROM jobs WHERE id = $1`, jobID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	return status, err
+}
+
+// NextLogSeq returns the next monotonically increasing seq for a job's log
+// lines.
+func (s *JobStore) NextLogSeq(ctx context.Context, jobID uuid.UUID) (int, error) {
+	var next int
+	err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(seq) + 1, 0) FROM job_logs WHERE job_id = $1`, jobID).Scan(&next)
+	return next, err
+}
+
+func (s *JobStore) AppendLog(ctx context.Context, jobID uuid.UUID, seq int, stream, data string) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO job_logs (job_id, seq, stream, data, ts) VALUES ($1, $2, $3, $4, $5)`,
+		jobID, seq, stream, data, time.Now())
+	return err
+}
+
+// LogsSince returns a job's log lines with seq greater than afterSeq, in
+// order, for httpapi's StreamJobLogs to tail.
+func (s *JobStore) LogsSince(ctx context.Context, jobID uuid.UUID, afterSeq int) ([]model.JobLog, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT seq, stream, data, ts FROM job_logs WHERE job_id = $1 AND seq > $2 ORDER BY seq`, jobID, afterSeq)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []model.JobLog
+	for rows.Next() {
+		var l model.JobLog
+		l.JobID = jobID
+		if err := rows.Scan(&l.Seq, &l.Stream, &l.Data, &l.Ts); err != nil {
+			return nil, err
+		}
+		logs = append(logs, l)
+	}
+	return logs, rows.Err()
+}
+
+// ListenForJob subscribes to the job_available channel on a dedicated
+// connection and invokes onNotify whenever a job is queued for agentID,
+// until ctx is cancelled.
+func (s *JobStore) ListenForJob(ctx context.Context, connStr string, agentID uuid.UUID, onNotify func()) *pq.Listener {
+	listener := pq.NewListener(connStr, 10*time.Second, time.Minute, nil)
+	listener.Listen(jobAvailableChan)
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	go func() {
+		for n := range listener.Notify {
+			if n != nil && n.Extra == agentID.String() {
+				onNotify()
+			}
+		}
+	}()
+
+	return listener
+}
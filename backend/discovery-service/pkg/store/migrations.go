@@ -0,0 +1,148 @@
+package store
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+type migration struct {
+	version int
+	name    string
+	upSQL   string
+	downSQL string
+}
+
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.Glob(migrationFiles, "migrations/*.up.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*migration{}
+	for _, upPath := range entries {
+		base := strings.TrimSuffix(strings.TrimPrefix(upPath, "migrations/"), ".up.sql")
+		version, name, err := parseMigrationName(base)
+		if err != nil {
+			return nil, err
+		}
+		upSQL, err := migrationFiles.ReadFile(upPath)
+		if err != nil {
+			return nil, err
+		}
+		downPath := "migrations/" + base + ".down.sql"
+		downSQL, err := migrationFiles.ReadFile(downPath)
+		if err != nil {
+			return nil, fmt.Errorf("missing down migration for %s: %w", base, err)
+		}
+		byVersion[version] = &migration{version: version, name: name, upSQL: string(upSQL), downSQL: string(downSQL)}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
+
+func parseMigrationName(base string) (int, string, error) {
+	parts := strings.SplitN(base, "_", 2)
+	if len(parts) != 2 {
+		return 0, "", fmt.Errorf("malformed migration filename %q", base)
+	}
+	version, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", fmt.Errorf("malformed migration version in %q: %w", base, err)
+	}
+	return version, parts[1], nil
+}
+
+// Migrate applies every migration newer than the current schema_migrations
+// version, in order, tracking progress in schema_migrations.
+func Migrate(db *sqlx.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at TIMESTAMPTZ DEFAULT NOW())`); err != nil {
+		return fmt.Errorf("ensuring schema_migrations table: %w", err)
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	var current int
+	if err := db.Get(&current, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`); err != nil {
+		return fmt.Errorf("reading current migration version: %w", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		tx, err := db.Beginx()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(m.upSQL); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("applying migration %d_%s: %w", m.version, m.name, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.version, m.name); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("recording migration %d_%s: %w", m.version, m.name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("committing migration %d_%s: %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+// Rollback reverts the most recently applied migration.
+func Rollback(db *sqlx.DB) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	var current int
+	if err := db.Get(&current, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`); err != nil {
+		return fmt.Errorf("reading current migration version: %w", err)
+	}
+	if current == 0 {
+		return nil
+	}
+
+	var target *migration
+	for i := range migrations {
+		if migrations[i].version == current {
+			target = &migrations[i]
+			break
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("no migration found for version %d", current)
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(target.downSQL); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("rolling back migration %d_%s: %w", target.version, target.name, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = $1`, target.version); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("un-recording migration %d_%s: %w", target.version, target.name, err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,189 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/requie/ag/backend/discovery-service/pkg/model"
+)
+
+// PostgresAgentRepository is the sqlx-backed AgentRepository implementation.
+type PostgresAgentRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresAgentRepository(db *sqlx.DB) *PostgresAgentRepository {
+	return &PostgresAgentRepository{db: db}
+}
+
+type agentRow struct {
+	ID            uuid.UUID      `db:"id"`
+	TenantID      uuid.NullUUID  `db:"tenant_id"`
+	Name          string         `db:"name"`
+	OwnerEmail    sql.NullString `db:"owner_email"`
+	ConnectorType string         `db:"connector_type"`
+	Status        string         `db:"status"`
+	Thumbprint    sql.NullString `db:"thumbprint"`
+	KeySet        []byte         `db:"keyset"`
+	Config        []byte         `db:"config"`
+	ConfigVersion int            `db:"config_version"`
+	CreatedAt     sql.NullTime   `db:"created_at"`
+	LastActive    sql.NullTime   `db:"last_active"`
+}
+
+func (row agentRow) toModel() *model.Agent {
+	a := &model.Agent{
+		ID:            row.ID,
+		Name:          row.Name,
+		OwnerEmail:    row.OwnerEmail.String,
+		ConnectorType: row.ConnectorType,
+		Status:        row.Status,
+		KeySet:        json.RawMessage(row.KeySet),
+		Config:        json.RawMessage(row.Config),
+		ConfigVersion: row.ConfigVersion,
+		CreatedAt:     row.CreatedAt.Time,
+	}
+	if row.TenantID.Valid {
+		id := row.TenantID.UUID
+		a.TenantID = &id
+	}
+	if row.Thumbprint.Valid {
+		tp := row.Thumbprint.String
+		a.Thumbprint = &tp
+	}
+	if row.LastActive.Valid {
+		la := row.LastActive.Time
+		a.LastActive = &la
+	}
+	return a
+}
+
+const agentColumns = `id, tenant_id, name, owner_email, connector_type, status, thumbprint, keyset, config, config_version, created_at, last_active`
+
+func (r *PostgresAgentRepository) Enroll(ctx context.Context, agent *model.Agent, claimCode string) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO agents (id, name, connector_type, status, thumbprint, keyset, config, config_version, claim_code, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		agent.ID, agent.Name, agent.ConnectorType, agent.Status, agent.Thumbprint, agent.KeySet, agent.Config, agent.ConfigVersion, claimCode, agent.CreatedAt)
+	return err
+}
+
+func (r *PostgresAgentRepository) Get(ctx context.Context, id uuid.UUID) (*model.Agent, error) {
+	var row agentRow
+	err := r.db.GetContext(ctx, &row, `SELECT `+agentColumns+` FROM agents WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return row.toModel(), nil
+}
+
+func (r *PostgresAgentRepository) GetByThumbprint(ctx context.Context, thumbprint string) (*model.Agent, error) {
+	var row agentRow
+	err := r.db.GetContext(ctx, &row, `SELECT `+agentColumns+` FROM agents WHERE thumbprint = $1`, thumbprint)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return row.toModel(), nil
+}
+
+func (r *PostgresAgentRepository) List(ctx context.Context, tenantID uuid.UUID) ([]model.Agent, error) {
+	var rows []agentRow
+	if err := r.db.SelectContext(ctx, &rows, `SELECT `+agentColumns+` FROM agents WHERE tenant_id = $1`, tenantID); err != nil {
+		return nil, err
+	}
+	agents := make([]model.Agent, 0, len(rows))
+	for _, row := range rows {
+		agents = append(agents, *row.toModel())
+	}
+	return agents, nil
+}
+
+// Claim binds a previously-enrolled, unclaimed agent to a tenant,
+// consuming its one-time claim code. It runs under withTxRetry since a
+// losing claim race should be retried rather than surfaced as a 500.
+func (r *PostgresAgentRepository) Claim(ctx context.Context, id uuid.UUID, claimCode string, tenantID uuid.UUID, ownerEmail string) (*model.Agent, error) {
+	var claimed *model.Agent
+	err := withTxRetry(ctx, r.db, func(tx *sqlx.Tx) error {
+		var row agentRow
+		err := tx.GetContext(ctx, &row,
+			`UPDATE agents SET status = 'claimed', owner_email = $1, tenant_id = $2, claim_code = NULL
+			 WHERE id = $3 AND claim_code = $4 AND status = 'unclaimed'
+			 RETURNING `+agentColumns,
+			ownerEmail, tenantID, id, claimCode)
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		claimed = row.toModel()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// Attach moves an unclaimed agent into a tenant (admin-only), replacing any
+// tenant it is already attached to.
+func (r *PostgresAgentRepository) Attach(ctx context.Context, id uuid.UUID, tenantID uuid.UUID) error {
+	return withTxRetry(ctx, r.db, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(ctx, `UPDATE agents SET tenant_id = $1 WHERE id = $2 AND status = 'unclaimed'`, tenantID, id)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// Detach clears an unclaimed agent's tenant so it can be re-attached.
+func (r *PostgresAgentRepository) Detach(ctx context.Context, id uuid.UUID) error {
+	return withTxRetry(ctx, r.db, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(ctx, `UPDATE agents SET tenant_id = NULL WHERE id = $1 AND status = 'unclaimed'`, id)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *PostgresAgentRepository) RotateKey(ctx context.Context, id uuid.UUID, thumbprint string, keyset json.RawMessage) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE agents SET thumbprint = $1, keyset = $2 WHERE id = $3`, thumbprint, keyset, id)
+	return err
+}
+
+func (r *PostgresAgentRepository) UpdateLastActive(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE agents SET last_active = NOW() WHERE id = $1`, id)
+	return err
+}
+
+func (r *PostgresAgentRepository) GetConnectorConfig(ctx context.Context, id uuid.UUID, tenantID uuid.UUID) (string, json.RawMessage, error) {
+	var connectorType string
+	var config []byte
+	err := r.db.QueryRowContext(ctx, `SELECT connector_type, config FROM agents WHERE id = $1 AND tenant_id = $2`, id, tenantID).
+		Scan(&connectorType, &config)
+	if err == sql.ErrNoRows {
+		return "", nil, ErrNotFound
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return connectorType, config, nil
+}
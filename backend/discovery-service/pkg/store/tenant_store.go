@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/requie/ag/backend/discovery-service/pkg/model"
+)
+
+// hashToken returns the digest of a bearer token as stored in api_tokens,
+// so a database read alone (backup, replica, injection elsewhere) never
+// hands over a live credential.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// TenantStore persists tenants and their scoped API tokens.
+type TenantStore struct {
+	db *sqlx.DB
+}
+
+func NewTenantStore(db *sqlx.DB) *TenantStore {
+	return &TenantStore{db: db}
+}
+
+func (s *TenantStore) CreateTenant(ctx context.Context, name string) (*model.Tenant, error) {
+	tenant := model.Tenant{ID: uuid.New(), Name: name, CreatedAt: time.Now()}
+	_, err := s.db.ExecContext(ctx, `INSERT INTO tenants (id, name, created_at) VALUES ($1, $2, $3)`, tenant.ID, tenant.Name, tenant.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+// MintToken records a caller-generated bearer token for tenantID and persists
+// only its hash; the plaintext token is returned on apiToken.Token so the caller can
+// hand it to the tenant, but it is never stored or retrievable again.
+func (s *TenantStore) MintToken(ctx context.Context, tenantID uuid.UUID, role model.Role, token string) (*model.APIToken, error) {
+	apiToken := model.APIToken{ID: uuid.New(), TenantID: tenantID, Token: token, Role: role, CreatedAt: time.Now()}
+	_, err := s.db.ExecContext(ctx, `INSERT INTO api_tokens (id, tenant_id, token_hash, role, created_at) VALUES ($1, $2, $3, $4, $5)`,
+		apiToken.ID, apiToken.TenantID, hashToken(token), apiToken.Role, apiToken.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &apiToken, nil
+}
+
+func (s *TenantStore) ListTokens(ctx context.Context, tenantID uuid.UUID) ([]model.APIToken, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, tenant_id, role, created_at, revoked_at FROM api_tokens WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []model.APIToken
+	for rows.Next() {
+		var t model.APIToken
+		var revokedAt sql.NullTime
+		if err := rows.Scan(&t.ID, &t.TenantID, &t.Role, &t.CreatedAt, &revokedAt); err != nil {
+			return nil, err
+		}
+		if revokedAt.Valid {
+			t.RevokedAt = &revokedAt.Time
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+// RevokeToken revokes a token, scoped to tenantID so one tenant can never
+// revoke another tenant's token by guessing or observing its ID.
+func (s *TenantStore) RevokeToken(ctx context.Context, tenantID, tokenID uuid.UUID) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE api_tokens SET revoked_at = $1 WHERE id = $2 AND tenant_id = $3 AND revoked_at IS NULL`, time.Now(), tokenID, tenantID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ResolveToken looks up the tenant and role bound to a live (non-revoked)
+// API token, for use by the tenant token auth middleware.
+func (s *TenantStore) ResolveToken(ctx context.Context, token string) (uuid.UUID, model.Role, error) {
+	var tenantID uuid.UUID
+	var role string
+	err := s.db.QueryRowContext(ctx, `SELECT tenant_id, role FROM api_tokens WHERE token_hash = $1 AND revoked_at IS NULL`, hashToken(token)).
+		Scan(&tenantID, &role)
+	if err == sql.ErrNoRows {
+		return uuid.Nil, "", ErrNotFound
+	}
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	return tenantID, model.Role(role), nil
+}
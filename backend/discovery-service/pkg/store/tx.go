@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+	txRetryAttempts        = 3
+)
+
+// withTxRetry runs fn inside a transaction, retrying with a short backoff
+// if Postgres aborts it for a serialization failure or deadlock — the
+// errors it raises when two concurrent claim/attach/detach calls collide.
+func withTxRetry(ctx context.Context, db *sqlx.DB, fn func(*sqlx.Tx) error) error {
+	var lastErr error
+	for attempt := 0; attempt < txRetryAttempts; attempt++ {
+		tx, err := db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		err = fn(tx)
+		if err != nil {
+			tx.Rollback()
+			if isRetryable(err) {
+				lastErr = err
+				time.Sleep(time.Duration(attempt+1) * 20 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+
+		if err := tx.Commit(); err != nil {
+			if isRetryable(err) {
+				lastErr = err
+				time.Sleep(time.Duration(attempt+1) * 20 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+		return nil
+	}
+	return lastErr
+}
+
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pgSerializationFailure || pqErr.Code == pgDeadlockDetected
+	}
+	return false
+}